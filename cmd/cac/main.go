@@ -0,0 +1,12 @@
+// Command cac conveniently converts audio files using ffmpeg.
+package main
+
+import (
+	"context"
+
+	"github.com/vanillaiice/cac/cmd"
+)
+
+func main() {
+	cmd.Run(context.Background())
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"time"
 
 	"github.com/urfave/cli/v3"
 	"github.com/vanillaiice/cac/convert"
@@ -32,6 +33,28 @@ func Run(ctx context.Context) {
 			}
 			return ctx, nil
 		},
+		Commands: []*cli.Command{
+			{
+				Name:      "run",
+				Usage:     "run a txtar manifest describing one or more conversion jobs",
+				ArgsUsage: "<manifest.txtar>",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "quiet",
+						Usage:   "only show error logs",
+						Value:   false,
+						Aliases: []string{"q"},
+					},
+				},
+				Action: func(ctx context.Context, c *cli.Command) error {
+					manifest := c.Args().First()
+					if manifest == "" {
+						return fmt.Errorf("manifest path is required")
+					}
+					return convert.RunManifest(manifest, c.Bool("quiet"))
+				},
+			},
+		},
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:  "command",
@@ -70,6 +93,14 @@ func Run(ctx context.Context) {
 				Usage:   "convert files with specified extensions",
 				Aliases: []string{"s"},
 			},
+			&cli.StringSliceFlag{
+				Name:  "include",
+				Usage: "only convert files whose path matches `PATTERN` (supports * ? and **, repeatable)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "exclude",
+				Usage: "do not convert files whose path matches `PATTERN` (supports * ? and **, repeatable)",
+			},
 			&cli.StringFlag{
 				Name:    "out-dir",
 				Usage:   "output directory of processed files",
@@ -88,18 +119,103 @@ func Run(ctx context.Context) {
 				Value:   false,
 				Aliases: []string{"D"},
 			},
+			&cli.BoolFlag{
+				Name:  "flatten",
+				Usage: "write every output file directly into out-dir instead of mirroring the source subdirectory structure",
+				Value: false,
+			},
 			&cli.BoolFlag{
 				Name:    "quiet",
 				Usage:   "only show error logs",
 				Value:   false,
 				Aliases: []string{"q"},
 			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "emit one JSON object per line for every lifecycle event, instead of human-readable text",
+				Value: false,
+			},
+			&cli.StringFlag{
+				Name:  "cache-path",
+				Usage: "location of the conversion cache index (default: convert.DefaultCachePath())",
+			},
+			&cli.BoolFlag{
+				Name:  "no-cache",
+				Usage: "do not skip files already converted according to the conversion cache",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  "rehash",
+				Usage: "ignore existing cache entries and recompute digests for every file",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  "verify",
+				Usage: "re-hash every output referenced by the cache/manifest and drop entries whose output is missing or has changed, before converting",
+				Value: false,
+			},
+			&cli.StringFlag{
+				Name:  "manifest",
+				Usage: "location of the conversion manifest (default: convert.DefaultManifestPath)",
+			},
+			&cli.BoolFlag{
+				Name:  "no-manifest",
+				Usage: "do not record or consult the conversion manifest",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "ignore the conversion manifest and reconvert every file, still recording a fresh entry for each",
+				Value: false,
+			},
+			&cli.IntFlag{
+				Name:  "shard",
+				Usage: "index of this shard, in [0, shards)",
+				Value: 0,
+			},
+			&cli.IntFlag{
+				Name:  "shards",
+				Usage: "total number of shards to split the directory walk across",
+				Value: 1,
+			},
+			&cli.IntFlag{
+				Name:  "shard-seed",
+				Usage: "perturb the shard assignment hash, to rotate files onto a different shard",
+				Value: 0,
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Usage: "kill a conversion job that runs longer than this duration (0 disables)",
+				Value: 0,
+			},
+			&cli.IntFlag{
+				Name:  "retries",
+				Usage: "retry a failed conversion this many additional times, with exponential backoff",
+				Value: 0,
+			},
+			&cli.DurationFlag{
+				Name:  "retry-backoff",
+				Usage: "base delay before the first retry, doubling (plus jitter) on each subsequent attempt",
+				Value: 500 * time.Millisecond,
+			},
 		},
 		Action: func(ctx context.Context, c *cli.Command) error {
 			if c.String("dir") == "" && len(c.StringSlice("files")) < 0 {
 				return fmt.Errorf("input directory (--dir) or file(s) (--files) are required")
 			}
 
+			if c.Int("shards") < 1 {
+				return fmt.Errorf("--shards must be at least 1")
+			}
+			if c.Int("shard") < 0 || c.Int("shard") >= c.Int("shards") {
+				return fmt.Errorf("--shard must be in [0, %d)", c.Int("shards"))
+			}
+			if c.Duration("retry-backoff") < 0 {
+				return fmt.Errorf("--retry-backoff must not be negative")
+			}
+
+			shell := convert.NewShell(os.Stdout, c.Bool("quiet"))
+
 			if c.String("dir") != "" {
 				if _, err := os.Stat(c.String("dir")); err != nil {
 					if errors.Is(err, os.ErrNotExist) {
@@ -112,10 +228,10 @@ func Run(ctx context.Context) {
 				if _, err := os.Stat(c.String("out-dir")); err != nil {
 					if errors.Is(err, os.ErrNotExist) {
 						if c.Bool("create-out-dir") {
-							if !c.Bool("quiet") {
-								log.Printf("creating output directory: %s\n", c.String("out-dir"))
+							if !c.Bool("json") {
+								shell.Printf("creating output directory: %s\n", c.String("out-dir"))
 							}
-							if err := os.MkdirAll(c.String("out-dir"), os.ModePerm); err != nil {
+							if err := shell.MkdirAll(c.String("out-dir")); err != nil {
 								return fmt.Errorf("failed to create output directory: %v", err)
 							}
 						} else {
@@ -126,23 +242,47 @@ func Run(ctx context.Context) {
 					}
 				}
 
-				if !c.Bool("quiet") {
-					log.Printf("starting audio conversion...\n")
-					log.Printf("source directory: %s\n", c.String("dir"))
-					log.Printf("target extension: %s\n", c.String("target"))
-					log.Printf("output directory: %s\n", c.String("out-dir"))
+				if !c.Bool("json") {
+					shell.Printf("starting audio conversion...\n")
+					shell.Printf("source directory: %s\n", c.String("dir"))
+					shell.Printf("target extension: %s\n", c.String("target"))
+					shell.Printf("output directory: %s\n", c.String("out-dir"))
 				}
 
-				return convert.ConvertDir(&convert.ConvertDirOpts{
-					Command:         c.String("command"),
-					Dir:             c.String("dir"),
-					Sources:         c.StringSlice("sources"),
-					Except:          c.StringSlice("except"),
-					TargetExtension: c.String("target"),
-					OutDir:          c.String("out-dir"),
-					DeleteOriginal:  c.Bool("delete"),
+				converter := &convert.Converter{
+					SourceDir:       c.String("dir"),
+					OutputDir:       c.String("out-dir"),
+					TargetExt:       c.String("target"),
+					SourceExts:      c.StringSlice("sources"),
+					ExemptExts:      c.StringSlice("except"),
+					CommandTemplate: c.String("command"),
+					Delete:          c.Bool("delete"),
+					Flatten:         c.Bool("flatten"),
+					Include:         c.StringSlice("include"),
+					Exclude:         c.StringSlice("exclude"),
 					Quiet:           c.Bool("quiet"),
-				})
+					JSON:            c.Bool("json"),
+					CachePath:       c.String("cache-path"),
+					NoCache:         c.Bool("no-cache"),
+					Rehash:          c.Bool("rehash"),
+					Verify:          c.Bool("verify"),
+					ManifestPath:    c.String("manifest"),
+					NoManifest:      c.Bool("no-manifest"),
+					Force:           c.Bool("force"),
+					Shard:           int(c.Int("shard")),
+					Shards:          int(c.Int("shards")),
+					ShardSeed:       int(c.Int("shard-seed")),
+					Timeout:         c.Duration("timeout"),
+					Retries:         int(c.Int("retries")),
+					RetryBackoff:    c.Duration("retry-backoff"),
+				}
+
+				// The reporter (text or JSON, per --json) prints per-job
+				// events and the final summary, so nothing more is
+				// printed here.
+				_, err := converter.Run(ctx)
+
+				return err
 			}
 
 			// TODO: consider using goroutines for parallel processing
@@ -150,13 +290,17 @@ func Run(ctx context.Context) {
 				errors := []error{}
 
 				for _, f := range c.StringSlice("files") {
-					err, _ := convert.ConvertFile(&convert.ConvertFileOpts{
+					err, _, _ := convert.ConvertFile(&convert.ConvertFileOpts{
 						Command:         c.String("command"),
 						Path:            f,
 						TargetExtension: c.String("target"),
 						OutDir:          c.String("out-dir"),
 						DeleteOriginal:  c.Bool("delete"),
 						Quiet:           c.Bool("quiet"),
+						Shell:           shell.WithPrefix(f),
+						Timeout:         c.Duration("timeout"),
+						Retries:         int(c.Int("retries")),
+						RetryBackoff:    c.Duration("retry-backoff"),
 					})
 					if err != nil {
 						errors = append(errors, err)
@@ -164,7 +308,7 @@ func Run(ctx context.Context) {
 				}
 
 				for _, err := range errors {
-					fmt.Errorf("%s", err)
+					log.Printf("%s", err)
 				}
 			}
 
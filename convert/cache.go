@@ -0,0 +1,134 @@
+package convert
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// CacheEntry records how an output file was produced, so a later run
+// can tell whether it is still up to date and whether the output
+// itself has been tampered with or gone missing since.
+type CacheEntry struct {
+	// SourceDigest folds in the source file's contents, the resolved
+	// ffmpeg command, the target extension, and the installed ffmpeg
+	// version, so it changes whenever any of those would produce a
+	// different output.
+	SourceDigest string `json:"source_digest"`
+	// OutputDigest is the sha256 of the output file as written by the
+	// conversion that produced SourceDigest.
+	OutputDigest string `json:"output_digest"`
+}
+
+// Cache is an on-disk, content-addressed record of already-converted
+// files. It maps an output path to the entry that produced it, so a
+// re-run over a large library only reconverts files that actually
+// changed.
+type Cache struct {
+	*diskIndex[CacheEntry]
+}
+
+// DefaultCachePath returns the default on-disk location of the cache
+// index, "~/.cache/cac/index.json" (or the OS equivalent).
+func DefaultCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ".cac-cache"
+	}
+	return filepath.Join(dir, "cac", "index.json")
+}
+
+// LoadCache loads the cache index at path, returning an empty cache if
+// the file does not yet exist.
+func LoadCache(path string) (*Cache, error) {
+	idx, err := loadDiskIndex[CacheEntry](path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cache: %w", err)
+	}
+	return &Cache{idx}, nil
+}
+
+// Get returns the cached entry for outputPath, if any.
+func (c *Cache) Get(outputPath string) (CacheEntry, bool) {
+	return c.get(outputPath)
+}
+
+// Set records entry as the cached entry for outputPath.
+func (c *Cache) Set(outputPath string, entry CacheEntry) {
+	c.set(outputPath, entry)
+}
+
+// Verify re-hashes every output file referenced by the cache and drops
+// entries whose output is missing or no longer matches OutputDigest, so
+// a later run won't skip a job whose output was edited or deleted
+// out-of-band. It returns the number of entries dropped.
+func (c *Cache) Verify() (int, error) {
+	return c.verify(func(e CacheEntry) string { return e.OutputDigest })
+}
+
+// Save writes the cache index back to disk, if it has changed since it
+// was loaded.
+func (c *Cache) Save() error {
+	return c.save()
+}
+
+// digestFile computes a digest from the input file's contents, the
+// resolved ffmpeg command, the target extension, and the installed
+// ffmpeg version, so that the digest changes whenever any of those
+// would produce a different output.
+func digestFile(inputPath, command, targetExtension string) (string, error) {
+	h := sha256.New()
+
+	f, err := os.Open(inputPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s for hashing: %w", inputPath, err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", inputPath, err)
+	}
+
+	version, err := ffmpegVersion()
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Fprintf(h, "\x00%s\x00%s\x00%s", command, targetExtension, version)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashFile returns the sha256 digest of the file's contents.
+func hashFile(path string) (string, error) {
+	h := sha256.New()
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ffmpegVersion returns the first line of `ffmpeg -version`, used to
+// invalidate cached digests whenever the installed ffmpeg changes.
+func ffmpegVersion() (string, error) {
+	out, err := exec.Command("ffmpeg", "-version").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine ffmpeg version: %w", err)
+	}
+
+	firstLine, _, _ := strings.Cut(string(out), "\n")
+	return strings.TrimSpace(firstLine), nil
+}
@@ -0,0 +1,77 @@
+package convert
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestConverterCacheSkipsUnchanged covers the content-addressed cache:
+// a second Run over an unchanged source tree should skip every file,
+// and -rehash should bypass that skip while still refreshing the
+// cache entry.
+func TestConverterCacheSkipsUnchanged(t *testing.T) {
+	shimDir := installFakeFfmpeg(t)
+	t.Setenv("PATH", shimDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	root := t.TempDir()
+	srcDir := filepath.Join(root, "src")
+	if err := os.MkdirAll(srcDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "a.wav"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cachePath := filepath.Join(root, ".cac-cache.json")
+	newConverter := func() *Converter {
+		return &Converter{
+			SourceDir:       srcDir,
+			OutputDir:       filepath.Join(root, "out"),
+			TargetExt:       ".mp3",
+			CommandTemplate: `ffmpeg -y -i "{{ .InputFile }}" "{{ .OutputFile }}"`,
+			NoManifest:      true,
+			CachePath:       cachePath,
+			Quiet:           true,
+		}
+	}
+
+	result, err := newConverter().Run(context.Background())
+	if err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	if result.Converted != 1 || result.Skipped != 0 {
+		t.Fatalf("first run: got %+v, want 1 converted, 0 skipped", result)
+	}
+
+	cache, err := LoadCache(cachePath)
+	if err != nil {
+		t.Fatalf("loading cache: %v", err)
+	}
+	entry, ok := cache.Get(filepath.Join(root, "out", "a.mp3"))
+	if !ok {
+		t.Fatal("expected a cache entry for a.mp3")
+	}
+	if entry.SourceDigest == "" || entry.OutputDigest == "" {
+		t.Fatalf("cache entry missing expected fields: %+v", entry)
+	}
+
+	result, err = newConverter().Run(context.Background())
+	if err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if result.Converted != 0 || result.Skipped != 1 {
+		t.Fatalf("second run: got %+v, want 0 converted, 1 skipped (cache should skip unchanged source)", result)
+	}
+
+	rehashed := newConverter()
+	rehashed.Rehash = true
+	result, err = rehashed.Run(context.Background())
+	if err != nil {
+		t.Fatalf("rehashed run: %v", err)
+	}
+	if result.Converted != 1 || result.Skipped != 0 {
+		t.Fatalf("rehashed run: got %+v, want 1 converted, 0 skipped (-rehash bypasses the cache)", result)
+	}
+}
@@ -0,0 +1,69 @@
+package convert
+
+import (
+	"fmt"
+	"time"
+)
+
+// ManifestEntry records how an output file was produced: the source
+// file it came from (path, size, mtime, and content digest), the
+// command template and ffmpeg version used, and the resulting output's
+// digest.
+type ManifestEntry struct {
+	SourcePath      string    `json:"source_path"`
+	SourceSize      int64     `json:"source_size"`
+	SourceModTime   time.Time `json:"source_mod_time"`
+	SourceDigest    string    `json:"source_digest"`
+	CommandTemplate string    `json:"command_template"`
+	FfmpegVersion   string    `json:"ffmpeg_version"`
+	OutputDigest    string    `json:"output_digest"`
+}
+
+// ConversionManifest is an on-disk, per-project audit trail of
+// conversions, keyed by output path. Unlike Cache (a content-addressed
+// index of already-seen source digests, shared across projects at
+// DefaultCachePath()), a ConversionManifest is meant to live alongside
+// a project's files, be committed or inspected, and record exactly
+// what produced each output.
+type ConversionManifest struct {
+	*diskIndex[ManifestEntry]
+}
+
+// DefaultManifestPath is the default location of the conversion
+// manifest, relative to the working directory.
+const DefaultManifestPath = ".cac-manifest.json"
+
+// LoadConversionManifest loads the manifest at path, returning an empty
+// manifest if the file does not yet exist.
+func LoadConversionManifest(path string) (*ConversionManifest, error) {
+	idx, err := loadDiskIndex[ManifestEntry](path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifest: %w", err)
+	}
+	return &ConversionManifest{idx}, nil
+}
+
+// Lookup returns the recorded entry for outputPath, if any.
+func (m *ConversionManifest) Lookup(outputPath string) (ManifestEntry, bool) {
+	return m.get(outputPath)
+}
+
+// Record stores entry as the manifest entry for outputPath.
+func (m *ConversionManifest) Record(outputPath string, entry ManifestEntry) {
+	m.set(outputPath, entry)
+}
+
+// Verify re-hashes every output file referenced by the manifest and
+// drops entries whose output is missing or no longer matches
+// OutputDigest, so a later run won't skip a job whose output was
+// edited or deleted out-of-band. It returns the number of entries
+// dropped.
+func (m *ConversionManifest) Verify() (int, error) {
+	return m.verify(func(e ManifestEntry) string { return e.OutputDigest })
+}
+
+// Save writes the manifest back to disk, if it has changed since it
+// was loaded.
+func (m *ConversionManifest) Save() error {
+	return m.save()
+}
@@ -0,0 +1,77 @@
+package convert
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestConverterManifestSkipsUnchanged covers the conversion manifest:
+// a second Run over an unchanged source tree should skip every file,
+// and -force should bypass that skip while still refreshing the
+// manifest entry.
+func TestConverterManifestSkipsUnchanged(t *testing.T) {
+	shimDir := installFakeFfmpeg(t)
+	t.Setenv("PATH", shimDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	root := t.TempDir()
+	srcDir := filepath.Join(root, "src")
+	if err := os.MkdirAll(srcDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "a.wav"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	manifestPath := filepath.Join(root, ".cac-manifest.json")
+	newConverter := func() *Converter {
+		return &Converter{
+			SourceDir:       srcDir,
+			OutputDir:       filepath.Join(root, "out"),
+			TargetExt:       ".mp3",
+			CommandTemplate: `ffmpeg -y -i "{{ .InputFile }}" "{{ .OutputFile }}"`,
+			NoCache:         true,
+			ManifestPath:    manifestPath,
+			Quiet:           true,
+		}
+	}
+
+	result, err := newConverter().Run(context.Background())
+	if err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	if result.Converted != 1 || result.Skipped != 0 {
+		t.Fatalf("first run: got %+v, want 1 converted, 0 skipped", result)
+	}
+
+	manifest, err := LoadConversionManifest(manifestPath)
+	if err != nil {
+		t.Fatalf("loading manifest: %v", err)
+	}
+	entry, ok := manifest.Lookup(filepath.Join(root, "out", "a.mp3"))
+	if !ok {
+		t.Fatal("expected a manifest entry for a.mp3")
+	}
+	if entry.SourcePath != filepath.Join(srcDir, "a.wav") || entry.SourceSize == 0 || entry.SourceDigest == "" || entry.CommandTemplate == "" || entry.FfmpegVersion == "" || entry.OutputDigest == "" {
+		t.Fatalf("manifest entry missing expected fields: %+v", entry)
+	}
+
+	result, err = newConverter().Run(context.Background())
+	if err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if result.Converted != 0 || result.Skipped != 1 {
+		t.Fatalf("second run: got %+v, want 0 converted, 1 skipped (manifest should skip unchanged source)", result)
+	}
+
+	forced := newConverter()
+	forced.Force = true
+	result, err = forced.Run(context.Background())
+	if err != nil {
+		t.Fatalf("forced run: %v", err)
+	}
+	if result.Converted != 1 || result.Skipped != 0 {
+		t.Fatalf("forced run: got %+v, want 1 converted, 0 skipped (-force bypasses the manifest)", result)
+	}
+}
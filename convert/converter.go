@@ -0,0 +1,448 @@
+package convert
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JobAction is the action ConvertFile is expected to take for a Job.
+type JobAction int
+
+const (
+	JobConvert JobAction = iota // the file will be converted to TargetExt.
+	JobMove                     // the file is already in TargetExt and will be moved/copied.
+)
+
+// Job describes one file a Converter plans to process.
+type Job struct {
+	InputPath  string
+	OutputPath string
+	Action     JobAction
+}
+
+// Result summarizes the outcome of a Converter.Run or Converter.RunJobs call.
+type Result struct {
+	Converted int
+	Moved     int
+	Skipped   int
+	Failed    int
+	TimedOut  int
+	// Retried is the total number of retry attempts made across all
+	// jobs in the run.
+	Retried int
+	Errors  []error
+}
+
+// Converter converts audio files under SourceDir to TargetExt using
+// ffmpeg commands generated from CommandTemplate. It is the library
+// entry point that cmd/cac's CLI wraps, so the conversion pipeline can
+// be embedded in other Go programs without shelling out to the cac
+// binary.
+type Converter struct {
+	SourceDir       string
+	OutputDir       string
+	TargetExt       string
+	SourceExts      []string
+	ExemptExts      []string
+	CommandTemplate string
+	MaxWorkers      int
+	Delete          bool
+	// Flatten writes every output file directly into OutputDir instead
+	// of mirroring the file's subdirectory path relative to SourceDir.
+	Flatten bool
+	Include []string
+	Exclude []string
+	// Logger receives progress output. Defaults to os.Stdout.
+	Logger io.Writer
+	Quiet  bool
+	// JSON emits one JSON object per line to Logger for every lifecycle
+	// event instead of human-readable text. Ignored if Reporter is set.
+	JSON bool
+	// Reporter, when set, overrides the Logger/Quiet/JSON-derived
+	// Reporter used to report lifecycle events.
+	Reporter Reporter
+
+	// Cache, when set, is consulted and updated as files convert so
+	// unchanged files are skipped on subsequent runs. If nil and
+	// NoCache is false, Run loads/saves the cache at CachePath,
+	// defaulting to DefaultCachePath() when that is empty.
+	Cache     *Cache
+	CachePath string
+	NoCache   bool
+	Rehash    bool
+	// Verify re-hashes every output referenced by the loaded cache and
+	// drops entries whose output is missing or has changed on disk
+	// since it was recorded, before planning any jobs.
+	Verify bool
+
+	// Manifest, when set, is consulted and updated as files convert, as
+	// in ConvertFileOpts. If nil and NoManifest is false, Run
+	// loads/saves the manifest at ManifestPath, defaulting to
+	// DefaultManifestPath when that is empty.
+	Manifest     *ConversionManifest
+	ManifestPath string
+	NoManifest   bool
+	// Force bypasses the manifest skip-check, as in ConvertFileOpts.
+	Force bool
+
+	// Shard and Shards split SourceDir's walk across invocations.
+	Shard  int
+	Shards int
+	// ShardSeed perturbs the shard assignment hash, so a consistently
+	// slow or unlucky shard can be rotated onto different files by
+	// re-running with a different seed.
+	ShardSeed int
+	// ownedByShard and skippedByShard count, respectively, the files
+	// Plan assigned to this shard and the files it skipped because
+	// they hashed to a different shard. Populated by Plan and reported
+	// in Run's summary event.
+	ownedByShard, skippedByShard int
+
+	// Timeout bounds how long each ffmpeg command is allowed to run.
+	Timeout time.Duration
+	// Retries is how many additional times a failed ffmpeg command is
+	// retried, with exponential backoff and jitter between attempts.
+	Retries int
+	// RetryBackoff is the base delay before the first retry. Defaults
+	// to 500ms when Retries is positive and this is unset.
+	RetryBackoff time.Duration
+
+	// PreHook and PostHook, when set, are shell command templates run,
+	// respectively, immediately before and immediately after each job
+	// converts. They accept the same {{.InputFile}}/{{.OutputFile}}
+	// placeholders as CommandTemplate, so e.g. a PostHook of
+	// `ffmpeg -i {{.OutputFile}} -af loudnorm ...` runs once per
+	// converted output rather than once for the whole run. A failing
+	// PreHook skips the job's conversion; a failing PostHook is
+	// reported as an error but doesn't change the job's own outcome.
+	PreHook  string
+	PostHook string
+}
+
+// Plan walks SourceDir and returns the ordered list of Jobs Run would
+// execute, without running ffmpeg or touching the filesystem. Callers
+// can filter or reorder the result before handing it to RunJobs.
+//
+// When Shards is greater than 1, Plan also records, on the Converter
+// itself, how many files were owned by this shard versus skipped
+// because they hashed to a different shard, so Run's summary event can
+// report shard identity alongside the usual counters.
+func (c *Converter) Plan(ctx context.Context) ([]Job, error) {
+	var jobs []Job
+	sharded := c.Shards > 1
+	c.ownedByShard, c.skippedByShard = 0, 0
+
+	err := filepath.WalkDir(c.SourceDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("error accessing %s: %w", path, err)
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		relPath, err := filepath.Rel(c.SourceDir, path)
+		if err != nil {
+			return fmt.Errorf("error computing relative path for %s: %w", path, err)
+		}
+
+		if sharded {
+			h := fnv.New32a()
+			fmt.Fprintf(h, "%d:%s", c.ShardSeed, relPath)
+			if int(h.Sum32()%uint32(c.Shards)) != c.Shard {
+				c.skippedByShard++
+				return nil
+			}
+			c.ownedByShard++
+		}
+
+		if len(c.Include) > 0 {
+			included, err := matchAny(c.Include, relPath)
+			if err != nil {
+				return fmt.Errorf("invalid include pattern: %w", err)
+			}
+			if !included {
+				return nil
+			}
+		}
+
+		if len(c.Exclude) > 0 {
+			excluded, err := matchAny(c.Exclude, relPath)
+			if err != nil {
+				return fmt.Errorf("invalid exclude pattern: %w", err)
+			}
+			if excluded {
+				return nil
+			}
+		}
+
+		ext := filepath.Ext(d.Name())
+
+		var shouldProcess bool
+		if len(c.SourceExts) > 0 {
+			shouldProcess = slices.Contains(c.SourceExts, ext)
+		} else {
+			shouldProcess = !slices.Contains(c.ExemptExts, ext)
+		}
+		if !shouldProcess {
+			return nil
+		}
+
+		action := JobConvert
+		if ext == c.TargetExt {
+			action = JobMove
+		}
+
+		jobs = append(jobs, Job{
+			InputPath:  path,
+			OutputPath: c.outputPath(path, ext),
+			Action:     action,
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// runHook expands tmpl against job's input/output paths and runs it
+// through shell, the same way a Job's conversion command is rendered
+// and run.
+func runHook(shell *Shell, tmpl string, job Job) error {
+	command, err := generateConvertCommand(tmpl, job.InputPath, job.OutputPath)
+	if err != nil {
+		return fmt.Errorf("failed to generate hook command: %w", err)
+	}
+	return shell.Run(command, 0)
+}
+
+// outputPath computes where a file at path (with extension ext) will be
+// written, mirroring path's subdirectory relative to SourceDir unless
+// Flatten is set.
+func (c *Converter) outputPath(path, ext string) string {
+	fileName := strings.TrimSuffix(filepath.Base(path), ext) + c.TargetExt
+
+	outDir := c.OutputDir
+	if !c.Flatten {
+		if relDir, err := filepath.Rel(c.SourceDir, filepath.Dir(path)); err == nil && relDir != "." {
+			outDir = filepath.Join(outDir, relDir)
+		}
+	}
+
+	return filepath.Join(outDir, fileName)
+}
+
+// Run plans and executes every job under SourceDir.
+func (c *Converter) Run(ctx context.Context) (Result, error) {
+	jobs, err := c.Plan(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+	return c.RunJobs(ctx, jobs)
+}
+
+// RunJobs executes the given jobs (typically produced by Plan, possibly
+// filtered or reordered by the caller) across MaxWorkers goroutines,
+// defaulting to runtime.NumCPU() when MaxWorkers is unset.
+func (c *Converter) RunJobs(ctx context.Context, jobs []Job) (Result, error) {
+	maxWorkers := c.MaxWorkers
+	if maxWorkers <= 0 {
+		maxWorkers = runtime.NumCPU()
+	}
+	sem := make(chan struct{}, maxWorkers)
+	var wg sync.WaitGroup
+
+	out := c.Logger
+	if out == nil {
+		out = os.Stdout
+	}
+	shell := NewShell(out, c.Quiet)
+
+	reporter := c.Reporter
+	if reporter == nil {
+		if c.JSON {
+			reporter = NewJSONReporter(out, c.Quiet)
+		} else {
+			reporter = NewTextReporter(out, c.Quiet)
+		}
+	}
+
+	cache := c.Cache
+	if cache == nil && !c.NoCache {
+		cachePath := c.CachePath
+		if cachePath == "" {
+			cachePath = DefaultCachePath()
+		}
+
+		var err error
+		cache, err = LoadCache(cachePath)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	if cache != nil && c.Verify {
+		dropped, err := cache.Verify()
+		if err != nil {
+			return Result{}, err
+		}
+		if !c.Quiet && dropped > 0 {
+			fmt.Fprintf(out, "dropped %d stale cache entries\n", dropped)
+		}
+	}
+
+	manifest := c.Manifest
+	if manifest == nil && !c.NoManifest {
+		manifestPath := c.ManifestPath
+		if manifestPath == "" {
+			manifestPath = DefaultManifestPath
+		}
+
+		var err error
+		manifest, err = LoadConversionManifest(manifestPath)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	if manifest != nil && c.Verify {
+		dropped, err := manifest.Verify()
+		if err != nil {
+			return Result{}, err
+		}
+		if !c.Quiet && dropped > 0 {
+			fmt.Fprintf(out, "dropped %d stale manifest entries\n", dropped)
+		}
+	}
+
+	reporter.Report(Event{Kind: EventPlan, Jobs: len(jobs)})
+
+	var mu sync.Mutex
+	var result Result
+
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+
+			select {
+			case <-ctx.Done():
+				return
+			case sem <- struct{}{}:
+			}
+			defer func() { <-sem }()
+
+			jobShell := shell.WithPrefix(job.InputPath)
+
+			if c.PreHook != "" {
+				if err := runHook(jobShell, c.PreHook, job); err != nil {
+					mu.Lock()
+					result.Errors = append(result.Errors, fmt.Errorf("pre hook for %s: %w", job.InputPath, err))
+					result.Failed++
+					mu.Unlock()
+					return
+				}
+			}
+
+			err, action, retries := ConvertFile(&ConvertFileOpts{
+				Command:         c.CommandTemplate,
+				Path:            job.InputPath,
+				TargetExtension: c.TargetExt,
+				OutputPath:      job.OutputPath,
+				DeleteOriginal:  c.Delete,
+				Quiet:           c.Quiet,
+				Shell:           jobShell,
+				Reporter:        reporter,
+				Cache:           cache,
+				NoCache:         c.NoCache,
+				Rehash:          c.Rehash,
+				Manifest:        manifest,
+				Force:           c.Force,
+				Timeout:         c.Timeout,
+				Retries:         c.Retries,
+				RetryBackoff:    c.RetryBackoff,
+			})
+
+			if c.PostHook != "" && err == nil && (action == fileActionConvert || action == fileActionMove) {
+				if hookErr := runHook(jobShell, c.PostHook, job); hookErr != nil {
+					err = fmt.Errorf("post hook for %s: %w", job.InputPath, hookErr)
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				result.Errors = append(result.Errors, err)
+			}
+			result.Retried += retries
+			switch action {
+			case fileActionFail:
+				result.Failed++
+			case fileActionConvert:
+				result.Converted++
+			case fileActionMove:
+				result.Moved++
+			case fileActionSkip:
+				result.Skipped++
+			case fileActionTimeout:
+				result.TimedOut++
+			}
+		}(job)
+	}
+
+	wg.Wait()
+
+	if cache != nil {
+		if err := cache.Save(); err != nil {
+			result.Errors = append(result.Errors, err)
+		}
+	}
+
+	if manifest != nil {
+		if err := manifest.Save(); err != nil {
+			result.Errors = append(result.Errors, err)
+		}
+	}
+
+	summary := Event{
+		Kind:      EventSummary,
+		Jobs:      len(jobs),
+		Converted: result.Converted,
+		Moved:     result.Moved,
+		Skipped:   result.Skipped,
+		Failed:    result.Failed,
+		TimedOut:  result.TimedOut,
+		Retried:   result.Retried,
+	}
+	if c.Shards > 1 {
+		summary.Shard = c.Shard
+		summary.Shards = c.Shards
+		summary.OwnedByShard = c.ownedByShard
+		summary.SkippedByShard = c.skippedByShard
+	}
+	reporter.Report(summary)
+
+	if len(result.Errors) > 0 {
+		return result, fmt.Errorf("completed with %d errors", len(result.Errors))
+	}
+
+	return result, nil
+}
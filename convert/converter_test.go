@@ -0,0 +1,121 @@
+package convert
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newShardSourceTree writes n numbered .wav files under a fresh src dir
+// and returns it.
+func newShardSourceTree(t *testing.T, n int) string {
+	t.Helper()
+
+	root := t.TempDir()
+	srcDir := filepath.Join(root, "src")
+	if err := os.MkdirAll(srcDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		name := filepath.Join(srcDir, fmt.Sprintf("f%d.wav", i))
+		if err := os.WriteFile(name, []byte("data"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return srcDir
+}
+
+// TestConverterPlanShardsPartitionFiles covers sharding: across all
+// Shards, Plan must assign every file to exactly one shard (disjoint
+// and exhaustive), matching the original ConvertDir behavior this
+// series re-added to Converter.
+func TestConverterPlanShardsPartitionFiles(t *testing.T) {
+	srcDir := newShardSourceTree(t, 7)
+
+	const shards = 3
+	seen := map[string]int{}
+	var total int
+	for shard := 0; shard < shards; shard++ {
+		c := &Converter{
+			SourceDir: srcDir,
+			OutputDir: filepath.Join(t.TempDir(), "out"),
+			TargetExt: ".mp3",
+			Shard:     shard,
+			Shards:    shards,
+		}
+		jobs, err := c.Plan(context.Background())
+		if err != nil {
+			t.Fatalf("shard %d: Plan: %v", shard, err)
+		}
+		for _, j := range jobs {
+			if owner, ok := seen[j.InputPath]; ok {
+				t.Fatalf("%s assigned to both shard %d and shard %d", j.InputPath, owner, shard)
+			}
+			seen[j.InputPath] = shard
+		}
+		total += len(jobs)
+	}
+
+	if total != 7 {
+		t.Fatalf("shards covered %d files, want 7 (disjoint union must be exhaustive)", total)
+	}
+}
+
+// capturingReporter records every Event it receives, for assertions on
+// the final summary.
+type capturingReporter struct {
+	events []Event
+}
+
+func (r *capturingReporter) Report(e Event) {
+	r.events = append(r.events, e)
+}
+
+// TestConverterRunReportsShardSummary covers the regression fixed
+// alongside this test: Run's summary event must carry shard identity
+// and the owned-vs-skipped-by-shard counts whenever Shards > 1.
+func TestConverterRunReportsShardSummary(t *testing.T) {
+	shimDir := installFakeFfmpeg(t)
+	t.Setenv("PATH", shimDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	srcDir := newShardSourceTree(t, 7)
+
+	reporter := &capturingReporter{}
+	c := &Converter{
+		SourceDir:       srcDir,
+		OutputDir:       filepath.Join(filepath.Dir(srcDir), "out"),
+		TargetExt:       ".mp3",
+		CommandTemplate: `ffmpeg -y -i "{{ .InputFile }}" "{{ .OutputFile }}"`,
+		NoCache:         true,
+		NoManifest:      true,
+		Shard:           1,
+		Shards:          3,
+		Reporter:        reporter,
+	}
+
+	if _, err := c.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var summary *Event
+	for i := range reporter.events {
+		if reporter.events[i].Kind == EventSummary {
+			summary = &reporter.events[i]
+		}
+	}
+	if summary == nil {
+		t.Fatal("no summary event reported")
+	}
+
+	if summary.Shard != 1 || summary.Shards != 3 {
+		t.Fatalf("summary shard identity = %d/%d, want 1/3", summary.Shard, summary.Shards)
+	}
+	if summary.OwnedByShard+summary.SkippedByShard != 7 {
+		t.Fatalf("summary owned (%d) + skipped (%d) = %d, want 7", summary.OwnedByShard, summary.SkippedByShard, summary.OwnedByShard+summary.SkippedByShard)
+	}
+	if summary.OwnedByShard != summary.Converted {
+		t.Fatalf("summary owned (%d) != converted (%d)", summary.OwnedByShard, summary.Converted)
+	}
+}
@@ -0,0 +1,120 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// diskIndex is the shared load/save/verify/dirty-tracking behavior
+// behind Cache and ConversionManifest: a JSON-encoded map of entries,
+// keyed by output path, persisted to path only when it has changed
+// since it was loaded.
+type diskIndex[T any] struct {
+	path string
+
+	mu      sync.Mutex
+	dirty   bool
+	Entries map[string]T `json:"entries"`
+}
+
+// loadDiskIndex loads the index at path, returning an empty one if the
+// file does not yet exist.
+func loadDiskIndex[T any](path string) (*diskIndex[T], error) {
+	idx := &diskIndex[T]{path: path, Entries: map[string]T{}}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return idx, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, idx); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if idx.Entries == nil {
+		idx.Entries = map[string]T{}
+	}
+
+	return idx, nil
+}
+
+// get returns the entry for key, if any.
+func (idx *diskIndex[T]) get(key string) (T, bool) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	entry, ok := idx.Entries[key]
+	return entry, ok
+}
+
+// set records entry for key.
+func (idx *diskIndex[T]) set(key string, entry T) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.Entries[key] = entry
+	idx.dirty = true
+}
+
+// verify re-hashes every file referenced by the index's keys and drops
+// entries for which the key no longer exists on disk or outputDigest
+// no longer matches the digest hashFile computes for it now. It
+// returns the number of entries dropped.
+func (idx *diskIndex[T]) verify(outputDigest func(T) string) (int, error) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	dropped := 0
+	for key, entry := range idx.Entries {
+		digest, err := hashFile(key)
+		if err != nil {
+			if os.IsNotExist(err) {
+				delete(idx.Entries, key)
+				dropped++
+				idx.dirty = true
+				continue
+			}
+			return dropped, fmt.Errorf("failed to verify output %s: %w", key, err)
+		}
+
+		if digest != outputDigest(entry) {
+			delete(idx.Entries, key)
+			dropped++
+			idx.dirty = true
+		}
+	}
+
+	return dropped, nil
+}
+
+// save writes the index back to disk, if it has changed since it was
+// loaded.
+func (idx *diskIndex[T]) save() error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if !idx.dirty {
+		return nil
+	}
+
+	if dir := filepath.Dir(idx.path); dir != "." {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", idx.path, err)
+		}
+	}
+
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", idx.path, err)
+	}
+
+	if err := os.WriteFile(idx.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", idx.path, err)
+	}
+
+	return nil
+}
@@ -1,13 +1,25 @@
 package convert
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
+// outputSize returns the size in bytes of the file at path, or 0 if it
+// cannot be stat'd.
+func outputSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
 // ConvertFileOpts hold options used when converting files.
 type ConvertFileOpts struct {
 	Command         string
@@ -16,6 +28,70 @@ type ConvertFileOpts struct {
 	OutDir          string
 	DeleteOriginal  bool
 	Quiet           bool
+	// SourceRoot, when set together with Flatten false, is used to
+	// compute Path's directory relative to it, which is then mirrored
+	// under OutDir. This preserves the source tree's subdirectory
+	// structure instead of writing every output file directly into
+	// OutDir. Ignored if OutputPath is set.
+	SourceRoot string
+	// Flatten writes every output file directly into OutDir, ignoring
+	// SourceRoot. This is the pre-existing behavior. Ignored if
+	// OutputPath is set.
+	Flatten bool
+	// OutputPath, when set, is used directly as the file's output path
+	// instead of deriving one from OutDir/SourceRoot/Flatten. Callers
+	// that already computed it once, such as Converter.RunJobs from a
+	// Plan-produced Job, should set it so the path a hook's
+	// {{.OutputFile}} resolves to can never drift from the path
+	// actually written.
+	OutputPath string
+	// Shell performs logging and runs the conversion command. If nil, a
+	// Shell is created from Quiet, writing to os.Stdout.
+	Shell *Shell
+	// Reporter receives lifecycle events for this job. If nil, a
+	// text Reporter is created from Quiet, writing to os.Stdout.
+	Reporter Reporter
+	// Cache, when set, is consulted before converting a file and
+	// updated after a successful conversion so that unchanged files
+	// are skipped on subsequent runs.
+	Cache *Cache
+	// NoCache disables both reading from and writing to Cache.
+	NoCache bool
+	// Rehash forces the digest to be recomputed and compared even if
+	// Cache already holds an entry for this output path.
+	Rehash bool
+	// Manifest, when set, is consulted before converting a file and
+	// updated after a successful conversion with a full audit-trail
+	// entry (source path, size, mtime, and digest; command template;
+	// ffmpeg version; output digest).
+	Manifest *ConversionManifest
+	// Force bypasses the Manifest skip-check, always reconverting, but
+	// Manifest (if set) is still updated with a fresh entry afterward.
+	Force bool
+	// Timeout, if positive, bounds how long the ffmpeg command is
+	// allowed to run before its process group is killed.
+	Timeout time.Duration
+	// Retries is how many additional times a failed ffmpeg command is
+	// retried, with exponential backoff and jitter between attempts.
+	// The default of 0 preserves the original no-retry behavior.
+	Retries int
+	// RetryBackoff is the base delay before the first retry; it
+	// doubles (plus jitter) on each subsequent attempt. Defaults to
+	// 500ms when Retries is positive and this is unset.
+	RetryBackoff time.Duration
+}
+
+// retryBackoff returns how long to wait before retry number attempt
+// (0-based), as base*2^attempt with up to 50% jitter, so many workers
+// retrying the same flaky mount don't all hammer it in lockstep. A
+// non-positive base (e.g. from a misconfigured --retry-backoff) waits
+// no time at all rather than panicking.
+func retryBackoff(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	d := base << attempt
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
 }
 
 // fileActionType is the type of action resulting from a ConvertFile call.
@@ -26,69 +102,205 @@ const (
 	fileActionConvert                       // converting the file succeeded.
 	fileActionMove                          // the file was moved/copied.
 	fileActionSkip                          // the file was skipped
+	fileActionTimeout                       // converting the file timed out
 )
 
-func ConvertFile(convertFileOpts *ConvertFileOpts) (error, fileActionType) {
+func ConvertFile(convertFileOpts *ConvertFileOpts) (error, fileActionType, int) {
+	shell := convertFileOpts.Shell
+	if shell == nil {
+		shell = NewShell(os.Stdout, convertFileOpts.Quiet)
+	}
+	reporter := convertFileOpts.Reporter
+	if reporter == nil {
+		reporter = NewTextReporter(os.Stdout, convertFileOpts.Quiet)
+	}
+
 	ext := filepath.Ext(convertFileOpts.Path)
-	fileName := strings.TrimSuffix(filepath.Base(convertFileOpts.Path), ext) + convertFileOpts.TargetExtension
-	outputPath := filepath.Join(convertFileOpts.OutDir, fileName)
+
+	outputPath := convertFileOpts.OutputPath
+	if outputPath == "" {
+		fileName := strings.TrimSuffix(filepath.Base(convertFileOpts.Path), ext) + convertFileOpts.TargetExtension
+
+		outDir := convertFileOpts.OutDir
+		if convertFileOpts.SourceRoot != "" && !convertFileOpts.Flatten {
+			if relDir, relErr := filepath.Rel(convertFileOpts.SourceRoot, filepath.Dir(convertFileOpts.Path)); relErr == nil && relDir != "." {
+				outDir = filepath.Join(outDir, relDir)
+			}
+		}
+		outputPath = filepath.Join(outDir, fileName)
+	}
+
+	if outDir := filepath.Dir(outputPath); outDir != "" && outDir != "." {
+		if err := shell.MkdirAll(outDir); err != nil {
+			return fmt.Errorf("failed to create output directory %s: %w", outDir, err), fileActionFail, 0
+		}
+	}
 
 	if ext == convertFileOpts.TargetExtension {
 		if convertFileOpts.Path == outputPath {
-			if !convertFileOpts.Quiet {
-				log.Printf("already in output directory - skipping: %s\n", convertFileOpts.Path)
-			}
-			return nil, fileActionSkip
+			reporter.Report(Event{Kind: EventSkipped, Input: convertFileOpts.Path, Reason: "already in output directory"})
+			return nil, fileActionSkip, 0
 		}
 
+		action := "copy"
+		if convertFileOpts.DeleteOriginal {
+			action = "move"
+		}
+
+		start := time.Now()
+		reporter.Report(Event{Kind: EventStart, Action: action, Input: convertFileOpts.Path, Output: outputPath})
+
 		if convertFileOpts.DeleteOriginal {
-			if !convertFileOpts.Quiet {
-				log.Printf("moving: %s -> %s\n", convertFileOpts.Path, outputPath)
-			}
 			if err := os.Rename(convertFileOpts.Path, outputPath); err != nil {
-				return fmt.Errorf("failed to move file %s: %w", convertFileOpts.Path, err), fileActionFail
+				wrapped := fmt.Errorf("failed to move file %s: %w", convertFileOpts.Path, err)
+				reporter.Report(Event{Kind: EventFailed, Action: action, Input: convertFileOpts.Path, Output: outputPath, Error: err.Error()})
+				return wrapped, fileActionFail, 0
 			}
 		} else {
-			if !convertFileOpts.Quiet {
-				log.Printf("copying: %s -> %s\n", convertFileOpts.Path, outputPath)
+			if err := shell.CopyFile(convertFileOpts.Path, outputPath); err != nil {
+				wrapped := fmt.Errorf("failed to copy file %s: %w", convertFileOpts.Path, err)
+				reporter.Report(Event{Kind: EventFailed, Action: action, Input: convertFileOpts.Path, Output: outputPath, Error: err.Error()})
+				return wrapped, fileActionFail, 0
 			}
-			if err := copyFile(convertFileOpts.Path, outputPath); err != nil {
-				return fmt.Errorf("failed to copy file %s: %w", convertFileOpts.Path, err), fileActionFail
+		}
+
+		reporter.Report(Event{Kind: EventCopied, Action: action, Input: convertFileOpts.Path, Output: outputPath, Bytes: outputSize(outputPath), Duration: time.Since(start)})
+
+		return nil, fileActionMove, 0
+	}
+
+	command, err := generateConvertCommand(convertFileOpts.Command, convertFileOpts.Path, outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to generate command for %s: %w", convertFileOpts.Path, err), fileActionFail, 0
+	}
+
+	useCache := convertFileOpts.Cache != nil && !convertFileOpts.NoCache
+	var digest string
+	if useCache {
+		if _, err := os.Stat(outputPath); err == nil {
+			digest, err = digestFile(convertFileOpts.Path, command, convertFileOpts.TargetExtension)
+			if err != nil {
+				return fmt.Errorf("failed to compute cache digest for %s: %w", convertFileOpts.Path, err), fileActionFail, 0
+			}
+
+			if cached, ok := convertFileOpts.Cache.Get(outputPath); ok && cached.SourceDigest == digest && !convertFileOpts.Rehash {
+				reporter.Report(Event{Kind: EventSkipped, Input: convertFileOpts.Path, Reason: "unchanged"})
+				return nil, fileActionSkip, 0
 			}
 		}
+	}
+
+	useManifest := convertFileOpts.Manifest != nil
+	var manifestSourceDigest string
+	if useManifest && !convertFileOpts.Force {
+		if _, err := os.Stat(outputPath); err == nil {
+			manifestSourceDigest, err = hashFile(convertFileOpts.Path)
+			if err != nil {
+				return fmt.Errorf("failed to hash %s: %w", convertFileOpts.Path, err), fileActionFail, 0
+			}
 
-		if !convertFileOpts.Quiet {
-			log.Printf("moved/copied: %s -> %s\n", convertFileOpts.Path, outputPath)
+			if entry, ok := convertFileOpts.Manifest.Lookup(outputPath); ok &&
+				entry.SourceDigest == manifestSourceDigest &&
+				entry.CommandTemplate == convertFileOpts.Command {
+				reporter.Report(Event{Kind: EventSkipped, Input: convertFileOpts.Path, Reason: "unchanged (manifest)"})
+				return nil, fileActionSkip, 0
+			}
 		}
+	}
 
-		return nil, fileActionMove
+	backoff := convertFileOpts.RetryBackoff
+	if backoff <= 0 {
+		backoff = 500 * time.Millisecond
 	}
 
-	if !convertFileOpts.Quiet {
-		log.Printf("converting: %s -> %s\n", convertFileOpts.Path, outputPath)
+	start := time.Now()
+	reporter.Report(Event{Kind: EventStart, Action: "convert", Input: convertFileOpts.Path, Output: outputPath})
+
+	var retries int
+	for {
+		err = shell.Run(command, convertFileOpts.Timeout)
+		if err == nil {
+			break
+		}
+
+		if retries >= convertFileOpts.Retries {
+			wrapped := fmt.Errorf("failed to convert %s: %w", convertFileOpts.Path, err)
+			reporter.Report(Event{Kind: EventFailed, Action: "convert", Input: convertFileOpts.Path, Output: outputPath, Error: err.Error(), Retries: retries})
+			if errors.Is(err, ErrTimeout) {
+				return wrapped, fileActionTimeout, retries
+			}
+			return wrapped, fileActionFail, retries
+		}
+
+		// A killed or failing ffmpeg run can leave a truncated output
+		// file behind; remove it so it doesn't get mistaken for a
+		// finished conversion before the retry runs.
+		os.Remove(outputPath)
+
+		wait := retryBackoff(backoff, retries)
+		reporter.Report(Event{Kind: EventRetrying, Action: "convert", Input: convertFileOpts.Path, Output: outputPath, Error: err.Error(), Retries: retries + 1})
+		time.Sleep(wait)
+		retries++
 	}
 
-	command, err := generateConvertCommand(convertFileOpts.Command, convertFileOpts.Path, outputPath)
-	if err != nil {
-		return fmt.Errorf("failed to generate command for %s: %w", convertFileOpts.Path, err), fileActionFail
+	if useCache {
+		if digest == "" {
+			digest, err = digestFile(convertFileOpts.Path, command, convertFileOpts.TargetExtension)
+			if err != nil {
+				return fmt.Errorf("failed to compute cache digest for %s: %w", convertFileOpts.Path, err), fileActionFail, retries
+			}
+		}
+
+		outputDigest, err := hashFile(outputPath)
+		if err != nil {
+			return fmt.Errorf("failed to hash output %s: %w", outputPath, err), fileActionFail, retries
+		}
+
+		convertFileOpts.Cache.Set(outputPath, CacheEntry{SourceDigest: digest, OutputDigest: outputDigest})
 	}
 
-	if err = runCommand(command, convertFileOpts.Quiet); err != nil {
-		return fmt.Errorf("failed to convert %s: %w", convertFileOpts.Path, err), fileActionFail
+	if useManifest {
+		sourceInfo, statErr := os.Stat(convertFileOpts.Path)
+		if statErr != nil {
+			return fmt.Errorf("failed to stat %s: %w", convertFileOpts.Path, statErr), fileActionFail, retries
+		}
+
+		if manifestSourceDigest == "" {
+			manifestSourceDigest, err = hashFile(convertFileOpts.Path)
+			if err != nil {
+				return fmt.Errorf("failed to hash %s: %w", convertFileOpts.Path, err), fileActionFail, retries
+			}
+		}
+
+		manifestOutputDigest, err := hashFile(outputPath)
+		if err != nil {
+			return fmt.Errorf("failed to hash output %s: %w", outputPath, err), fileActionFail, retries
+		}
+
+		ffmpegVer, err := ffmpegVersion()
+		if err != nil {
+			return fmt.Errorf("failed to determine ffmpeg version for %s: %w", convertFileOpts.Path, err), fileActionFail, retries
+		}
+
+		convertFileOpts.Manifest.Record(outputPath, ManifestEntry{
+			SourcePath:      convertFileOpts.Path,
+			SourceSize:      sourceInfo.Size(),
+			SourceModTime:   sourceInfo.ModTime(),
+			SourceDigest:    manifestSourceDigest,
+			CommandTemplate: convertFileOpts.Command,
+			FfmpegVersion:   ffmpegVer,
+			OutputDigest:    manifestOutputDigest,
+		})
 	}
 
 	if convertFileOpts.DeleteOriginal {
-		if !convertFileOpts.Quiet {
-			log.Printf("deleting original file: %s\n", convertFileOpts.Path)
-		}
+		shell.Printf("deleting original file: %s\n", convertFileOpts.Path)
 		if err := os.Remove(convertFileOpts.Path); err != nil {
-			return fmt.Errorf("failed to delete original file %s: %w", convertFileOpts.Path, err), fileActionFail
+			return fmt.Errorf("failed to delete original file %s: %w", convertFileOpts.Path, err), fileActionFail, retries
 		}
 	}
 
-	if !convertFileOpts.Quiet {
-		log.Printf("converted: %s -> %s\n", convertFileOpts.Path, outputPath)
-	}
+	reporter.Report(Event{Kind: EventConverted, Action: "convert", Input: convertFileOpts.Path, Output: outputPath, Bytes: outputSize(outputPath), Duration: time.Since(start), Retries: retries})
 
-	return nil, fileActionConvert
+	return nil, fileActionConvert, retries
 }
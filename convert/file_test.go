@@ -0,0 +1,19 @@
+package convert
+
+import (
+	"testing"
+	"time"
+)
+
+// TestRetryBackoffNonPositiveBase covers the panic fixed by this
+// change: a non-positive base (e.g. a misconfigured negative
+// --retry-backoff) must not make rand.Int63n see a non-positive n.
+func TestRetryBackoffNonPositiveBase(t *testing.T) {
+	for _, base := range []time.Duration{-5 * time.Second, -1, 0} {
+		for attempt := 0; attempt < 3; attempt++ {
+			if got := retryBackoff(base, attempt); got != 0 {
+				t.Errorf("retryBackoff(%v, %d) = %v, want 0", base, attempt, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package convert
+
+import (
+	"regexp"
+	"strings"
+)
+
+// matchAny reports whether path matches at least one of the given glob
+// patterns. Patterns support the usual `*` and `?` wildcards plus `**`,
+// which matches across path separators (e.g. `podcasts/**/raw/*.wav`
+// matches any `.wav` file nested arbitrarily deep under a `raw` directory).
+func matchAny(patterns []string, path string) (bool, error) {
+	for _, pattern := range patterns {
+		ok, err := matchGlob(pattern, path)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchGlob reports whether path matches pattern, where pattern may
+// contain `*`, `?`, and `**` wildcards. Paths are expected to use `/` as
+// the separator, as produced by filepath.ToSlash.
+func matchGlob(pattern, path string) (bool, error) {
+	re, err := globToRegexp(pattern)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(filepathToSlash(path)), nil
+}
+
+// globToRegexp translates a glob pattern into an equivalent anchored
+// regular expression.
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+
+	runes := []rune(filepathToSlash(pattern))
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '*':
+			if i+1 < len(runes) && runes[i+1] == '*' {
+				i++
+				if i+1 < len(runes) && runes[i+1] == '/' {
+					// `**/` matches zero or more whole path segments, each
+					// followed by its separator, so what follows is always
+					// anchored at a segment boundary rather than free to
+					// start mid-segment.
+					sb.WriteString("(?:.*/)?")
+					i++
+				} else {
+					// `**` matches zero or more path segments, including the separators.
+					sb.WriteString(".*")
+				}
+			} else {
+				// `*` matches within a single path segment.
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(runes[i])))
+		}
+	}
+
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
+
+func filepathToSlash(s string) string {
+	return strings.ReplaceAll(s, "\\", "/")
+}
@@ -0,0 +1,150 @@
+package convert
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// JobSpec describes one section of a txtar manifest: a directory
+// conversion job plus optional shell hooks run before/after it.
+type JobSpec struct {
+	Name            string
+	Dir             string
+	Include         []string
+	Exclude         []string
+	Sources         []string
+	Except          []string
+	TargetExtension string
+	OutDir          string
+	Command         string
+	DeleteOriginal  bool
+	Flatten         bool
+	// Pre and Post are shell command templates, run respectively
+	// immediately before and immediately after each file in the job
+	// converts. They accept the same {{.InputFile}}/{{.OutputFile}}
+	// placeholders as Command.
+	Pre  string
+	Post string
+}
+
+// ParseManifest parses a txtar manifest describing one or more
+// conversion jobs. Each "-- job:<name> --" section holds `key = value`
+// lines; keys that accept multiple values (include, exclude, sources,
+// except) may repeat across lines and are accumulated in order.
+func ParseManifest(data []byte) ([]*JobSpec, error) {
+	archive := parseTxtar(data)
+
+	var jobs []*JobSpec
+	for _, f := range archive.Files {
+		name, ok := strings.CutPrefix(f.Name, "job:")
+		if !ok {
+			continue
+		}
+
+		job := &JobSpec{Name: strings.TrimSpace(name), TargetExtension: ".mp3"}
+		for _, line := range strings.Split(string(f.Data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, fmt.Errorf("job %q: invalid line %q, expected key = value", job.Name, line)
+			}
+			key = strings.TrimSpace(key)
+			value = strings.TrimSpace(value)
+
+			switch key {
+			case "dir":
+				job.Dir = value
+			case "include":
+				job.Include = append(job.Include, value)
+			case "exclude":
+				job.Exclude = append(job.Exclude, value)
+			case "sources":
+				job.Sources = append(job.Sources, value)
+			case "except":
+				job.Except = append(job.Except, value)
+			case "target":
+				job.TargetExtension = value
+			case "out-dir":
+				job.OutDir = value
+			case "command":
+				job.Command = value
+			case "delete":
+				job.DeleteOriginal = value == "true"
+			case "flatten":
+				job.Flatten = value == "true"
+			case "pre":
+				job.Pre = value
+			case "post":
+				job.Post = value
+			default:
+				return nil, fmt.Errorf("job %q: unknown key %q", job.Name, key)
+			}
+		}
+
+		if job.Dir == "" {
+			return nil, fmt.Errorf("job %q: missing required key %q", job.Name, "dir")
+		}
+		if job.Command == "" {
+			job.Command = `ffmpeg -y -i "{{ .InputFile }}" "{{ .OutputFile }}"`
+		}
+		if job.OutDir == "" {
+			job.OutDir = job.Dir
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	if len(jobs) == 0 {
+		return nil, fmt.Errorf("manifest has no \"job:<name>\" sections")
+	}
+
+	return jobs, nil
+}
+
+// RunManifest reads and runs the txtar manifest at path, executing each
+// job's pre hook, directory conversion, and post hook in sequence.
+func RunManifest(path string, quiet bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read manifest %s: %w", path, err)
+	}
+
+	jobs, err := ParseManifest(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+
+	shell := NewShell(os.Stdout, quiet)
+
+	for _, job := range jobs {
+		jobShell := shell.WithPrefix(job.Name)
+		jobShell.Printf("=== running job %q ===\n", job.Name)
+
+		converter := &Converter{
+			SourceDir:       job.Dir,
+			OutputDir:       job.OutDir,
+			TargetExt:       job.TargetExtension,
+			SourceExts:      job.Sources,
+			ExemptExts:      job.Except,
+			CommandTemplate: job.Command,
+			Delete:          job.DeleteOriginal,
+			Flatten:         job.Flatten,
+			Include:         job.Include,
+			Exclude:         job.Exclude,
+			Quiet:           quiet,
+			PreHook:         job.Pre,
+			PostHook:        job.Post,
+		}
+		if _, err := converter.Run(context.Background()); err != nil {
+			return fmt.Errorf("job %q: %w", job.Name, err)
+		}
+	}
+
+	return nil
+}
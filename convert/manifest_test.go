@@ -0,0 +1,118 @@
+package convert
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRunManifestSourcesFilter covers the regression from
+// https://github.com/vanillaiice/cac: a manifest job's `sources =`
+// filter must match each file's own extension, not the job's target
+// extension, so e.g. `sources = .wav` actually restricts a job to wav
+// files.
+func TestRunManifestSourcesFilter(t *testing.T) {
+	shimDir := installFakeFfmpeg(t)
+	t.Setenv("PATH", shimDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	root := t.TempDir()
+	srcDir := filepath.Join(root, "src")
+	if err := os.MkdirAll(srcDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "keep.wav"), []byte("wav"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "skip.flac"), []byte("flac"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	outDir := filepath.Join(root, "out")
+	manifest := []byte(`-- job:wav-only --
+dir = ` + srcDir + `
+out-dir = ` + outDir + `
+sources = .wav
+target = .mp3
+`)
+
+	manifestPath := filepath.Join(root, "manifest.txtar")
+	if err := os.WriteFile(manifestPath, manifest, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// RunManifest's jobs record to the conversion manifest at its
+	// default, cwd-relative path; run from root so that doesn't leak a
+	// .cac-manifest.json into the repo.
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(cwd) })
+
+	if err := RunManifest(manifestPath, true); err != nil {
+		t.Fatalf("RunManifest: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(outDir, "keep.mp3")); err != nil {
+		t.Errorf("expected keep.mp3 to have been converted: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "skip.mp3")); err == nil {
+		t.Errorf("skip.flac should have been excluded by the sources filter")
+	}
+}
+
+// TestRunManifestPostHookPerFile covers the regression where a job's
+// post hook was run once for the whole job with no template expansion,
+// so a hook referencing {{.OutputFile}} (as documented) could not work.
+// It must instead run once per converted file, with the placeholder
+// expanded to that file's own output path.
+func TestRunManifestPostHookPerFile(t *testing.T) {
+	shimDir := installFakeFfmpeg(t)
+	t.Setenv("PATH", shimDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	root := t.TempDir()
+	srcDir := filepath.Join(root, "src")
+	if err := os.MkdirAll(srcDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.wav", "b.wav"} {
+		if err := os.WriteFile(filepath.Join(srcDir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	outDir := filepath.Join(root, "out")
+	manifest := []byte(`-- job:tag --
+dir = ` + srcDir + `
+out-dir = ` + outDir + `
+target = .mp3
+post = touch {{ .OutputFile }}.tagged
+`)
+
+	manifestPath := filepath.Join(root, "manifest.txtar")
+	if err := os.WriteFile(manifestPath, manifest, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(cwd) })
+
+	if err := RunManifest(manifestPath, true); err != nil {
+		t.Fatalf("RunManifest: %v", err)
+	}
+
+	for _, name := range []string{"a.mp3", "b.mp3"} {
+		if _, err := os.Stat(filepath.Join(outDir, name) + ".tagged"); err != nil {
+			t.Errorf("expected post hook to have tagged %s: %v", name, err)
+		}
+	}
+}
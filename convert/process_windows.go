@@ -0,0 +1,33 @@
+//go:build windows
+
+package convert
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup puts cmd in its own process group so it can be
+// signalled independently of this process.
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= syscall.CREATE_NEW_PROCESS_GROUP
+}
+
+// terminateProcessGroup asks cmd's process to exit. Windows has no
+// SIGTERM equivalent for arbitrary processes, so this goes straight to
+// Kill; killProcessGroup is then a no-op on the second attempt.
+func terminateProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		_ = cmd.Process.Kill()
+	}
+}
+
+// killProcessGroup force-kills cmd's process.
+func killProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		_ = cmd.Process.Kill()
+	}
+}
@@ -0,0 +1,208 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// EventKind identifies the stage of a conversion job an Event reports
+// on.
+type EventKind string
+
+const (
+	EventPlan      EventKind = "plan"      // a run's job list has been computed.
+	EventStart     EventKind = "start"     // a job has begun.
+	EventConverted EventKind = "converted" // a job finished via ffmpeg.
+	EventCopied    EventKind = "copied"    // a job finished by moving/copying.
+	EventSkipped   EventKind = "skipped"   // a job was skipped.
+	EventRetrying  EventKind = "retrying"  // a job failed and is about to retry.
+	EventFailed    EventKind = "failed"    // a job failed or timed out.
+	EventSummary   EventKind = "summary"   // the run has finished.
+)
+
+// Event describes one lifecycle event of a Converter run. Reporter
+// implementations render it either as a human-readable log line or as
+// a line of JSON.
+type Event struct {
+	Kind EventKind `json:"event"`
+
+	// Action distinguishes, for Start/Converted/Copied, whether the job
+	// ran ffmpeg ("convert") or moved/copied an already-matching file
+	// ("move" or "copy").
+	Action string `json:"action,omitempty"`
+	// Reason explains, for Skipped, why the job was skipped (e.g.
+	// "already in output directory" or "unchanged").
+	Reason string `json:"reason,omitempty"`
+
+	Input    string        `json:"input,omitempty"`
+	Output   string        `json:"output,omitempty"`
+	Bytes    int64         `json:"bytes,omitempty"`
+	Duration time.Duration `json:"duration_ms,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	// Retries is, for Retrying, the retry attempt about to be made
+	// (1-based); for Converted/Failed, the number of retries that were
+	// needed before the job finished.
+	Retries int `json:"retries,omitempty"`
+
+	// Jobs/Converted/Moved/Skipped/Failed/TimedOut are only set on a
+	// Summary event, mirroring Result's counters.
+	Jobs      int `json:"jobs,omitempty"`
+	Converted int `json:"converted,omitempty"`
+	Moved     int `json:"moved,omitempty"`
+	Skipped   int `json:"skipped,omitempty"`
+	Failed    int `json:"failed,omitempty"`
+	TimedOut  int `json:"timed_out,omitempty"`
+	// Retried is, on a Summary event, the total number of retry attempts
+	// made across all jobs in the run.
+	Retried int `json:"retried,omitempty"`
+
+	// Shard/Shards/OwnedByShard/SkippedByShard are only set on a
+	// Summary event when the run used sharding (Shards > 1): Shard and
+	// Shards identify this run's shard, and OwnedByShard/SkippedByShard
+	// count the files Plan assigned to this shard versus skipped
+	// because they hashed to a different shard.
+	Shard          int `json:"shard,omitempty"`
+	Shards         int `json:"shards,omitempty"`
+	OwnedByShard   int `json:"owned_by_shard,omitempty"`
+	SkippedByShard int `json:"skipped_by_shard,omitempty"`
+}
+
+// MarshalJSON renders Duration in milliseconds, since a time.Duration's
+// default encoding (nanoseconds) is awkward for consumers.
+func (e Event) MarshalJSON() ([]byte, error) {
+	type alias Event
+	return json.Marshal(struct {
+		alias
+		Duration int64 `json:"duration_ms,omitempty"`
+	}{alias(e), e.Duration.Milliseconds()})
+}
+
+// Reporter receives lifecycle events from a Converter run. Implementations
+// must be safe for concurrent use, since jobs run on many goroutines.
+type Reporter interface {
+	Report(Event)
+}
+
+// textReporter renders events as the traditional human-readable log
+// lines.
+type textReporter struct {
+	out   io.Writer
+	quiet bool
+	mu    sync.Mutex
+}
+
+// NewTextReporter returns a Reporter that writes human-readable log
+// lines to out, the default used when no other Reporter is configured.
+// Only Failed and Summary events are still printed when quiet;
+// everything else, including Skipped, is suppressed.
+func NewTextReporter(out io.Writer, quiet bool) Reporter {
+	return &textReporter{out: out, quiet: quiet}
+}
+
+func (r *textReporter) printf(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fmt.Fprintf(r.out, format, args...)
+}
+
+func (r *textReporter) Report(e Event) {
+	switch e.Kind {
+	case EventPlan:
+		if !r.quiet {
+			r.printf("planned %d job(s)\n", e.Jobs)
+		}
+	case EventStart:
+		if r.quiet {
+			return
+		}
+		switch e.Action {
+		case "move":
+			r.printf("%s: moving -> %s\n", e.Input, e.Output)
+		case "copy":
+			r.printf("%s: copying -> %s\n", e.Input, e.Output)
+		default:
+			r.printf("%s: converting -> %s\n", e.Input, e.Output)
+		}
+	case EventConverted:
+		if !r.quiet {
+			if e.Retries > 0 {
+				r.printf("%s: converted -> %s (%s, %d retries)\n", e.Input, e.Output, e.Duration.Round(time.Millisecond), e.Retries)
+			} else {
+				r.printf("%s: converted -> %s (%s)\n", e.Input, e.Output, e.Duration.Round(time.Millisecond))
+			}
+		}
+	case EventCopied:
+		if !r.quiet {
+			verb := "copied"
+			if e.Action == "move" {
+				verb = "moved"
+			}
+			r.printf("%s: %s -> %s\n", e.Input, verb, e.Output)
+		}
+	case EventSkipped:
+		if !r.quiet {
+			r.printf("%s: skipped (%s)\n", e.Input, e.Reason)
+		}
+	case EventRetrying:
+		if !r.quiet {
+			r.printf("%s: failed (%s), retrying (attempt %d)\n", e.Input, e.Error, e.Retries)
+		}
+	case EventFailed:
+		if e.Retries > 0 {
+			r.printf("%s: failed after %d retries: %s\n", e.Input, e.Retries, e.Error)
+		} else {
+			r.printf("%s: failed: %s\n", e.Input, e.Error)
+		}
+	case EventSummary:
+		r.printf("\n=== conversion summary ===\n")
+		if e.Shards > 0 {
+			r.printf("shard: %d/%d (owned: %d, skipped due to sharding: %d)\n", e.Shard, e.Shards, e.OwnedByShard, e.SkippedByShard)
+		}
+		r.printf("files converted: %d\n", e.Converted)
+		r.printf("files moved/copied: %d\n", e.Moved)
+		r.printf("files skipped: %d\n", e.Skipped)
+		r.printf("files failed: %d\n", e.Failed)
+		r.printf("files timed out: %d\n", e.TimedOut)
+		r.printf("total files processed: %d\n", e.Converted+e.Moved+e.Skipped)
+		if e.Retried > 0 {
+			r.printf("retry attempts: %d\n", e.Retried)
+		}
+	}
+}
+
+// jsonReporter emits one JSON object per line (newline-delimited JSON)
+// for every event, so a run can be piped into jq or consumed by a CI
+// system or GUI instead of scraped from decorated text.
+type jsonReporter struct {
+	mu    sync.Mutex
+	enc   *json.Encoder
+	quiet bool
+}
+
+// NewJSONReporter returns a Reporter that writes one JSON object per
+// line to out for every event. As with NewTextReporter, only Failed
+// and Summary events are still emitted when quiet; everything else,
+// including Skipped, is suppressed, so -quiet and -json compose
+// cleanly.
+func NewJSONReporter(out io.Writer, quiet bool) Reporter {
+	return &jsonReporter{enc: json.NewEncoder(out), quiet: quiet}
+}
+
+func (r *jsonReporter) Report(e Event) {
+	if r.quiet {
+		switch e.Kind {
+		case EventFailed, EventSummary:
+		default:
+			return
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Encode never fails for an Event: it has no channels, funcs, or
+	// cycles, so the error is not worth surfacing to callers.
+	_ = r.enc.Encode(e)
+}
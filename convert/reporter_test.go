@@ -0,0 +1,42 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestJSONReporterQuiet covers -quiet composing with -json: only
+// Failed and Summary events should reach the stream, matching
+// NewTextReporter's quiet behavior.
+func TestJSONReporterQuiet(t *testing.T) {
+	var buf bytes.Buffer
+	reporter := NewJSONReporter(&buf, true)
+
+	reporter.Report(Event{Kind: EventPlan, Jobs: 1})
+	reporter.Report(Event{Kind: EventStart, Input: "a.wav"})
+	reporter.Report(Event{Kind: EventSkipped, Input: "a.wav", Reason: "unchanged"})
+	reporter.Report(Event{Kind: EventFailed, Input: "b.wav", Error: "boom"})
+	reporter.Report(Event{Kind: EventSummary, Converted: 1, Failed: 1})
+
+	var kinds []string
+	dec := json.NewDecoder(strings.NewReader(buf.String()))
+	for dec.More() {
+		var e Event
+		if err := dec.Decode(&e); err != nil {
+			t.Fatal(err)
+		}
+		kinds = append(kinds, string(e.Kind))
+	}
+
+	want := []string{string(EventFailed), string(EventSummary)}
+	if len(kinds) != len(want) {
+		t.Fatalf("got events %v, want %v", kinds, want)
+	}
+	for i, k := range want {
+		if kinds[i] != k {
+			t.Fatalf("got events %v, want %v", kinds, want)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package convert
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestScript runs every testdata/script/*.txt archive. Each archive's
+// leading text is a single `cac <flags>` invocation (parsed the same
+// way the CLI parses os.Args, minus the binary name); its "-- name --"
+// sections lay out the starting filesystem under a temp dir, except
+// for names under "want/", which instead describe the expected output
+// tree. A fake `ffmpeg` shim is put on PATH so archives don't need real
+// codecs or audio fixtures: it copies its input to its output and
+// answers `-version` with a fixed string.
+func TestScript(t *testing.T) {
+	paths, err := filepath.Glob("testdata/script/*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no testdata/script/*.txt archives found")
+	}
+
+	shimDir := installFakeFfmpeg(t)
+	t.Setenv("PATH", shimDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	for _, path := range paths {
+		t.Run(strings.TrimSuffix(filepath.Base(path), ".txt"), func(t *testing.T) {
+			runScript(t, path)
+		})
+	}
+}
+
+func runScript(t *testing.T, path string) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := parseTxtar(data)
+
+	root := t.TempDir()
+	want := map[string]string{}
+	var wantGone []string
+	for _, f := range archive.Files {
+		if rel, ok := strings.CutPrefix(f.Name, "want/gone/"); ok {
+			wantGone = append(wantGone, rel)
+			continue
+		}
+		if rel, ok := strings.CutPrefix(f.Name, "want/"); ok {
+			want[rel] = string(f.Data)
+			continue
+		}
+		dest := filepath.Join(root, f.Name)
+		if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(dest, f.Data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	args, err := splitCommand(strings.TrimSpace(string(archive.Comment)))
+	if err != nil {
+		t.Fatalf("invalid invocation line: %v", err)
+	}
+	if len(args) == 0 || args[0] != "cac" {
+		t.Fatalf("script must start with a `cac <flags>` invocation, got %q", archive.Comment)
+	}
+
+	converter, outDirRel := parseScriptArgs(t, root, args[1:])
+
+	result, runErr := converter.Run(context.Background())
+
+	// A "-- want/$error --" section (content is ignored) asserts that
+	// Run returns an error, e.g. to cover error aggregation.
+	if _, wantErr := want["$error"]; wantErr {
+		if runErr == nil {
+			t.Fatalf("expected a run error, got none (result: %+v)", result)
+		}
+		delete(want, "$error")
+	} else if runErr != nil {
+		t.Fatalf("unexpected run error: %v", runErr)
+	}
+
+	gotFiles := map[string]string{}
+	err = filepath.WalkDir(filepath.Join(root, outDirRel), func(p string, d os.DirEntry, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		gotFiles[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, wantData := range want {
+		gotData, ok := gotFiles[name]
+		if !ok {
+			t.Errorf("missing expected output file %q", name)
+			continue
+		}
+		if gotData != wantData {
+			t.Errorf("file %q: got %q, want %q", name, gotData, wantData)
+		}
+		delete(gotFiles, name)
+	}
+	for name := range gotFiles {
+		t.Errorf("unexpected output file %q", name)
+	}
+
+	// A "-- want/gone/<path> --" section (content is ignored) asserts
+	// that <path>, relative to root, no longer exists, e.g. to cover
+	// -delete.
+	for _, rel := range wantGone {
+		if _, err := os.Stat(filepath.Join(root, rel)); !os.IsNotExist(err) {
+			t.Errorf("expected %q to have been removed", rel)
+		}
+	}
+}
+
+// parseScriptArgs maps a minimal set of cac CLI flags onto a Converter
+// rooted at root, for the paths that TestScript cares about covering.
+// It also returns the out-dir flag's value, relative to root, so the
+// caller can scope its output-tree assertions to it.
+func parseScriptArgs(t *testing.T, root string, args []string) (_ *Converter, outDirRel string) {
+	t.Helper()
+
+	fs := flag.NewFlagSet("cac", flag.ContinueOnError)
+	dir := fs.String("dir", "", "")
+	outDir := fs.String("out-dir", ".", "")
+	target := fs.String("target", ".mp3", "")
+	sources := fs.String("sources", "", "")
+	except := fs.String("except", "", "")
+	command := fs.String("command", `ffmpeg -y -i "{{ .InputFile }}" "{{ .OutputFile }}"`, "")
+	deleteOriginal := fs.Bool("delete", false, "")
+	flatten := fs.Bool("flatten", false, "")
+	include := fs.String("include", "", "")
+	exclude := fs.String("exclude", "", "")
+	timeout := fs.Duration("timeout", 0, "")
+	retries := fs.Int("retries", 0, "")
+	retryBackoff := fs.Duration("retry-backoff", 0, "")
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("failed to parse script args %v: %v", args, err)
+	}
+
+	return &Converter{
+		SourceDir:       filepath.Join(root, *dir),
+		OutputDir:       filepath.Join(root, *outDir),
+		TargetExt:       *target,
+		SourceExts:      splitNonEmpty(*sources),
+		ExemptExts:      splitNonEmpty(*except),
+		CommandTemplate: *command,
+		Delete:          *deleteOriginal,
+		Flatten:         *flatten,
+		Include:         splitNonEmpty(*include),
+		Exclude:         splitNonEmpty(*exclude),
+		NoCache:         true,
+		NoManifest:      true,
+		Quiet:           true,
+		Timeout:         *timeout,
+		Retries:         *retries,
+		RetryBackoff:    *retryBackoff,
+	}, *outDir
+}
+
+// splitNonEmpty splits a comma-separated flag value, returning nil for
+// an empty string.
+func splitNonEmpty(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// installFakeFfmpeg writes a fake ffmpeg shim to a temp dir and returns
+// that dir, so tests never need a real ffmpeg install or audio
+// fixtures: the shim just copies its input file to its output file,
+// and answers `-version` with a fixed banner. An input path containing
+// "bad" always fails; one containing "hang" sleeps long enough for a
+// script's -timeout to kill it; one containing "flaky" fails its first
+// two attempts (tracked in a sibling ".attempts" file) and succeeds on
+// the third, so -retries can be exercised.
+func installFakeFfmpeg(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	name := "ffmpeg"
+	script := "#!/bin/sh\n" +
+		"if [ \"$1\" = \"-version\" ]; then\n" +
+		"  echo 'ffmpeg version fake-shim'\n" +
+		"  exit 0\n" +
+		"fi\n" +
+		"in=\"\"\n" +
+		"prev=\"\"\n" +
+		"for arg in \"$@\"; do\n" +
+		"  if [ \"$prev\" = \"-i\" ]; then\n" +
+		"    in=\"$arg\"\n" +
+		"  fi\n" +
+		"  prev=\"$arg\"\n" +
+		"  out=\"$arg\"\n" +
+		"done\n" +
+		"case \"$in\" in\n" +
+		"  *bad*) echo \"fake ffmpeg: refusing to convert $in\" >&2; exit 1 ;;\n" +
+		"  *hang*) sleep 2 ;;\n" +
+		"esac\n" +
+		"case \"$in\" in\n" +
+		"  *flaky*)\n" +
+		"    attempts=\"$in.attempts\"\n" +
+		"    count=0\n" +
+		"    [ -f \"$attempts\" ] && count=$(cat \"$attempts\")\n" +
+		"    count=$((count + 1))\n" +
+		"    echo \"$count\" > \"$attempts\"\n" +
+		"    if [ \"$count\" -le 2 ]; then\n" +
+		"      echo \"fake ffmpeg: flaky failure $count for $in\" >&2\n" +
+		"      exit 1\n" +
+		"    fi\n" +
+		"    ;;\n" +
+		"esac\n" +
+		"cp \"$in\" \"$out\"\n"
+
+	if runtime.GOOS == "windows" {
+		t.Skip("fake ffmpeg shim is a POSIX shell script")
+	}
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
@@ -0,0 +1,119 @@
+package convert
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Shell serializes logging and directory setup across the goroutines
+// that drive concurrent conversions, and is the single entry point used
+// to invoke ffmpeg (or any other shell command). It is modeled on
+// cmd/go/internal/work.Shell.
+type Shell struct {
+	out    io.Writer
+	quiet  bool
+	prefix string
+
+	printMu *sync.Mutex
+	mkdirs  *mkdirCache
+}
+
+// mkdirCache remembers which directories have already been created so
+// that concurrent jobs writing into the same output subdirectory only
+// call os.MkdirAll once.
+type mkdirCache struct {
+	mu   sync.Mutex
+	done map[string]error
+}
+
+// NewShell creates a root Shell that writes to out unless quiet is set.
+// Shells derived from it via WithPrefix share its printer lock and
+// mkdir cache.
+func NewShell(out io.Writer, quiet bool) *Shell {
+	return &Shell{
+		out:     out,
+		quiet:   quiet,
+		printMu: &sync.Mutex{},
+		mkdirs:  &mkdirCache{done: map[string]error{}},
+	}
+}
+
+// WithPrefix returns a Shell derived from s that prefixes every log
+// line with prefix. The derived Shell shares s's printer lock and mkdir
+// cache, so output from many derived shells never interleaves mid-line
+// and a directory is only created once no matter how many derived
+// shells try to create it.
+func (s *Shell) WithPrefix(prefix string) *Shell {
+	derived := *s
+	derived.prefix = prefix
+	return &derived
+}
+
+// Printf writes a log line, unless the shell is quiet.
+func (s *Shell) Printf(format string, args ...any) {
+	if s.quiet {
+		return
+	}
+
+	msg := fmt.Sprintf(format, args...)
+	if s.prefix != "" {
+		msg = s.prefix + ": " + msg
+	}
+
+	s.printMu.Lock()
+	defer s.printMu.Unlock()
+	fmt.Fprint(s.out, msg)
+}
+
+// MkdirAll creates dir and any missing parents, same as os.MkdirAll,
+// except repeated calls for the same directory across derived shells
+// only touch the filesystem once.
+func (s *Shell) MkdirAll(dir string) error {
+	s.mkdirs.mu.Lock()
+	defer s.mkdirs.mu.Unlock()
+
+	if err, ok := s.mkdirs.done[dir]; ok {
+		return err
+	}
+
+	err := os.MkdirAll(dir, os.ModePerm)
+	s.mkdirs.done[dir] = err
+	return err
+}
+
+// Run executes command, the same way the package-level runCommand
+// helper does, but buffers its combined output and writes it through
+// Printf as a single block, so output from commands running in
+// different goroutines never interleaves mid-line.
+func (s *Shell) Run(command string, timeout time.Duration) error {
+	var buf strings.Builder
+
+	var stdout, stderr io.Writer = io.Discard, io.Discard
+	if !s.quiet {
+		stdout, stderr = &buf, &buf
+	}
+
+	err := runCommand(command, stdout, stderr, timeout)
+
+	if buf.Len() > 0 {
+		s.Printf("%s", buf.String())
+	}
+
+	return err
+}
+
+// CopyFile copies a file from src to dst, preserving file permissions,
+// creating dst's parent directory if needed.
+func (s *Shell) CopyFile(src, dst string) error {
+	if dir := filepath.Dir(dst); dir != "" && dir != "." {
+		if err := s.MkdirAll(dir); err != nil {
+			return err
+		}
+	}
+	return copyFile(src, dst)
+}
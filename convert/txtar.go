@@ -0,0 +1,87 @@
+package convert
+
+import (
+	"bytes"
+	"strings"
+)
+
+// txtarFile is a single named section of a txtar archive.
+type txtarFile struct {
+	Name string
+	Data []byte
+}
+
+// txtarArchive is a parsed txtar archive: free-form text followed by a
+// sequence of "-- name --" delimited file sections. This is the same
+// format used by cmd/go's script_test.go and golang.org/x/tools/txtar.
+type txtarArchive struct {
+	Comment []byte
+	Files   []txtarFile
+}
+
+var (
+	txtarMarker    = []byte("-- ")
+	txtarMarkerEnd = []byte(" --")
+)
+
+// parseTxtar parses data as a txtar archive.
+func parseTxtar(data []byte) *txtarArchive {
+	a := new(txtarArchive)
+
+	var name string
+	a.Comment, name, data = findTxtarMarker(data)
+	for name != "" {
+		f := txtarFile{Name: name}
+		f.Data, name, data = findTxtarMarker(data)
+		a.Files = append(a.Files, f)
+	}
+
+	return a
+}
+
+// findTxtarMarker scans data for the next "-- name --" marker line and
+// returns the data preceding it, the parsed name, and the data
+// following the marker line. If no marker is found, it returns all of
+// data (newline-terminated) as before, with an empty name.
+func findTxtarMarker(data []byte) (before []byte, name string, after []byte) {
+	var i int
+	for {
+		if name, after = isTxtarMarker(data[i:]); name != "" {
+			return data[:i], name, after
+		}
+		j := bytes.IndexByte(data[i:], '\n')
+		if j < 0 {
+			return fixTxtarNL(data), "", nil
+		}
+		i += j + 1
+	}
+}
+
+// isTxtarMarker reports whether data begins with a "-- name --" marker
+// line, returning the trimmed name and the data following that line.
+func isTxtarMarker(data []byte) (name string, after []byte) {
+	if !bytes.HasPrefix(data, txtarMarker) {
+		return "", nil
+	}
+
+	line := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		line, after = data[:i], data[i+1:]
+	} else {
+		after = nil
+	}
+
+	if !bytes.HasSuffix(line, txtarMarkerEnd) || len(line) < len(txtarMarker)+len(txtarMarkerEnd) {
+		return "", nil
+	}
+
+	return strings.TrimSpace(string(line[len(txtarMarker) : len(line)-len(txtarMarkerEnd)])), after
+}
+
+// fixTxtarNL ensures data ends with a trailing newline.
+func fixTxtarNL(data []byte) []byte {
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		data = append(data[:len(data):len(data)], '\n')
+	}
+	return data
+}
@@ -1,16 +1,26 @@
 package convert
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/mattn/go-shellwords"
 )
 
+// killGracePeriod is how long runCommand waits after sending SIGTERM to a
+// timed-out process group before escalating to SIGKILL.
+const killGracePeriod = 5 * time.Second
+
+// ErrTimeout is returned by runCommand when the command did not finish
+// within the requested timeout.
+var ErrTimeout = errors.New("command timed out")
+
 // convertCmdTemplateData holds the template data for the conversion command.
 type convertCmdTemplateData struct {
 	InputFile  string
@@ -42,8 +52,13 @@ func splitCommand(command string) ([]string, error) {
 	return shellwords.Parse(command)
 }
 
-// runCommand executes a shell command and handles output based on quiet flag.
-func runCommand(command string, quiet bool) error {
+// runCommand executes a shell command, writing its stdout/stderr to the
+// given writers (pass io.Discard to suppress either). The command runs
+// in its own process group; if timeout is positive and the command has
+// not finished by then, the whole group is sent SIGTERM and, if it
+// hasn't exited after killGracePeriod, SIGKILL. A timeout that expires
+// returns an error wrapping ErrTimeout.
+func runCommand(command string, stdout, stderr io.Writer, timeout time.Duration) error {
 	var cmd *exec.Cmd
 	commandParts, err := splitCommand(command)
 	if err != nil {
@@ -59,12 +74,35 @@ func runCommand(command string, quiet bool) error {
 		cmd = exec.Command(commandParts[0], commandParts[1:]...)
 	}
 
-	if !quiet {
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	setProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	if timeout <= 0 {
+		return cmd.Wait()
 	}
 
-	return cmd.Run()
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		terminateProcessGroup(cmd)
+		select {
+		case <-done:
+		case <-time.After(killGracePeriod):
+			killProcessGroup(cmd)
+			<-done
+		}
+		return fmt.Errorf("%w after %s: %s", ErrTimeout, timeout, command)
+	}
 }
 
 // copyFile copies a file from src to dst, preserving file permissions.
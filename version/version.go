@@ -0,0 +1,6 @@
+// Package version holds the cac build version, overridden at release
+// build time via -ldflags "-X github.com/vanillaiice/cac/version.Version=...".
+package version
+
+// Version is the current cac version. It is "dev" for local/source builds.
+var Version = "dev"